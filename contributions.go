@@ -0,0 +1,133 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "runtime"
+    "strings"
+    "sync"
+    "time"
+)
+
+// contributionWeeks is how many weeks of history the heat-map covers,
+// roughly the ~6 months requested.
+const contributionWeeks = 26
+
+// runContributions discovers git projects under directories and renders a
+// terminal heat-map of commits authored by email over the last ~6 months,
+// in the style of gogitlocalstats.
+func runContributions(ctx context.Context, directories []string, repo Repository, email string, opts scanOptions) {
+    since := time.Now().AddDate(0, -6, 0)
+
+    dates := collectContributionDates(ctx, directories, repo, email, since, opts)
+
+    var buckets [7][contributionWeeks]int
+    now := time.Now()
+    for _, d := range dates {
+        weeksAgo := int(now.Sub(d).Hours() / (24 * 7))
+        if weeksAgo < 0 || weeksAgo >= contributionWeeks {
+            continue
+        }
+        col := contributionWeeks - 1 - weeksAgo
+        buckets[int(d.Weekday())][col]++
+    }
+
+    printHeatmap(buckets, email, len(dates))
+}
+
+// collectContributionDates walks directories for git projects and collects
+// the commit dates authored by email, no older than since, using a bounded
+// worker pool in the same shape as runScan.
+func collectContributionDates(ctx context.Context, directories []string, repo Repository, email string, since time.Time, opts scanOptions) []time.Time {
+    workers := opts.workers
+    if workers <= 0 {
+        workers = runtime.NumCPU()
+    }
+
+    paths := make(chan string)
+
+    go func() {
+        defer close(paths)
+
+        for _, rootDir := range directories {
+            rootDir = strings.TrimSpace(rootDir)
+            fmt.Printf("🔍 Scanning: %s\n", rootDir)
+
+            for path := range discoverGitDirs(ctx, rootDir, opts) {
+                select {
+                case paths <- path:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }
+    }()
+
+    var mu sync.Mutex
+    var dates []time.Time
+    var wg sync.WaitGroup
+    wg.Add(workers)
+
+    for i := 0; i < workers; i++ {
+        go func() {
+            defer wg.Done()
+
+            for projectPath := range paths {
+                opCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+                repoDates, err := repo.CommitDatesByAuthor(opCtx, projectPath, email, since)
+                cancel()
+
+                if err != nil {
+                    fmt.Printf("!❌ [%s] Skipping project due to error: %v\n", projectPath, err)
+                    continue
+                }
+
+                mu.Lock()
+                dates = append(dates, repoDates...)
+                mu.Unlock()
+            }
+        }()
+    }
+
+    wg.Wait()
+
+    return dates
+}
+
+// printHeatmap renders a weeks-as-columns, weekdays-as-rows heat-map using
+// ANSI 256-color escape codes, similar to GitHub's contribution graph.
+func printHeatmap(buckets [7][contributionWeeks]int, email string, total int) {
+    weekdayLabels := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+    fmt.Printf("\n📊 Contributions for %s (last ~6 months, %d commits)\n\n", email, total)
+
+    for day := 0; day < len(buckets); day++ {
+        fmt.Printf("%-3s ", weekdayLabels[day])
+        for week := 0; week < contributionWeeks; week++ {
+            fmt.Print(contributionColorBlock(buckets[day][week]))
+        }
+        fmt.Println()
+    }
+
+    fmt.Println()
+}
+
+// contributionColorBlock maps a commit count to a colored block using the
+// same bucket thresholds GitHub's contribution graph uses.
+func contributionColorBlock(count int) string {
+    var color int
+    switch {
+    case count == 0:
+        color = 236
+    case count <= 2:
+        color = 22
+    case count <= 5:
+        color = 28
+    case count <= 9:
+        color = 34
+    default:
+        color = 40
+    }
+
+    return fmt.Sprintf("\x1b[48;5;%dm  \x1b[0m", color)
+}
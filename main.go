@@ -2,23 +2,27 @@ package main
 
 import (
     "context"
-    "encoding/csv"
-    "errors"
     "flag"
     "fmt"
     "os"
-    "os/exec"
-    "path/filepath"
+    "os/signal"
+    "runtime"
     "sort"
     "strings"
     "time"
 )
 
 type GitProject struct {
-    Path           string
-    ProjectName    string
-    RemoteRepo     string
-    LastCommitDate time.Time
+    Path           string       `json:"path"`
+    ProjectName    string       `json:"projectName"`
+    RemoteRepo     string       `json:"remoteRepo"`
+    LastCommitDate time.Time    `json:"lastCommitDate"`
+    Branch         string       `json:"branch,omitempty"`
+    Ahead          int          `json:"ahead"`
+    Behind         int          `json:"behind"`
+    Dirty          bool         `json:"dirty"`
+    CommitCount    int          `json:"commitCount"`
+    TopAuthors     []AuthorStat `json:"topAuthors,omitempty"`
 }
 
 // Додайте цей helper для примусового виводу
@@ -47,6 +51,17 @@ func (s *stringSliceFlag) Set(value string) error {
 func main() {
     var directories stringSliceFlag
     flag.Var(&directories, "directory", "Path to a directory to search (can be repeated)")
+    backend := flag.String("backend", "exec", "Git backend to use: exec (shell out to the git binary) or native (go-git)")
+    workers := flag.Int("workers", runtime.NumCPU(), "Number of repositories to inspect concurrently")
+    verbose := flag.Bool("verbose", false, "Print per-directory walk chatter in addition to the structured SCAN progress lines")
+    mode := flag.String("mode", "inventory", "Operation mode: inventory (CSV report, default) or contributions (author activity heat-map)")
+    email := flag.String("email", "", "Author email to aggregate commits for in --mode=contributions")
+    format := flag.String("format", "csv", "Output format for --mode=inventory: csv, json, ndjson, or md")
+    output := flag.String("output", "", "Output path for --mode=inventory (default: git_projects_report.<format>); use - for stdout")
+    deep := flag.Bool("deep", false, "Also collect branch, ahead/behind, dirty state, commit count, and top authors (slower, one extra git op each)")
+    topAuthors := flag.Int("top-authors", 3, "Number of top authors (by commits in the last 90 days) to collect with --deep")
+    var exclude stringSliceFlag
+    flag.Var(&exclude, "exclude", "Glob of a directory name to skip during the walk, e.g. node_modules (can be repeated)")
 
     flag.Parse()
 
@@ -55,153 +70,172 @@ func main() {
         os.Exit(1)
     }
 
-    var gitProjects []GitProject
+    repo, err := newRepository(*backend)
+    if err != nil {
+        fmt.Printf("❌ %v\n", err)
+        os.Exit(1)
+    }
 
-    for _, rootDir := range directories {
-        rootDir = strings.TrimSpace(rootDir)
-        fmt.Printf("🔍 Scanning: %s\n", rootDir)
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
 
-        err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-            if info != nil && info.IsDir() {
-                printAndFlush(fmt.Sprintf("Entering: %s\n", path))
-            }
-
-            if err != nil {
-                // Логуємо помилку (наприклад, "permission denied") і повертаємо nil
-                // для продовження обходу інших частин дерева.
-                fmt.Printf("🚫 Skipped due to error in %s: %v\n", path, err)
-                return nil // Продовжуємо обхід
-            }
-            if info.IsDir() && strings.HasSuffix(path, "/.git") {
-                printAndFlush(path)
-                projectPath := filepath.Dir(path)
-                projectName := filepath.Base(projectPath)
-                remoteRepo, lastCommitDate, err := getGitInfo(projectPath)
-                if err != nil {
-                    fmt.Printf("!❌ [%s] Skipping project due to error: %v\n", projectPath, err) // Позначаємо пропуск
-                    return nil
-                }
-                gitProjects = append(gitProjects, GitProject{
-                    Path:           projectPath,
-                    ProjectName:    projectName,
-                    RemoteRepo:     remoteRepo,
-                    LastCommitDate: lastCommitDate,
-                })
-
-                printAndFlush("+")
-            } else if info.IsDir() {
-                printAndFlush(path)
-                printAndFlush(".")
-                return nil
-            }
-            return nil
-        })
+    scanOpts := scanOptions{
+        workers:    *workers,
+        verbose:    *verbose,
+        deep:       *deep,
+        exclude:    exclude,
+        topAuthors: *topAuthors,
+    }
 
-        if err != nil {
-            fmt.Printf("🚫 Error while scanning '%s': %v\n", rootDir, err)
-            continue
+    if *mode == "contributions" {
+        if *email == "" {
+            fmt.Println("❌ --mode=contributions requires --email=you@example.com")
+            os.Exit(1)
         }
+        runContributions(ctx, directories, repo, *email, scanOpts)
+        return
     }
 
-    // Sorting
-    sort.Slice(gitProjects, func(i, j int) bool {
-        if gitProjects[i].RemoteRepo != "" && gitProjects[j].RemoteRepo != "" {
-            if gitProjects[i].RemoteRepo == gitProjects[j].RemoteRepo {
-                return gitProjects[i].LastCommitDate.After(gitProjects[j].LastCommitDate)
-            }
+    results := runScan(ctx, directories, repo, scanOpts)
 
-            return gitProjects[i].RemoteRepo < gitProjects[j].RemoteRepo
-        }
-
-        return gitProjects[i].ProjectName < gitProjects[j].ProjectName
-    })
+    outputPath := *output
+    if outputPath == "" {
+        outputPath = "git_projects_report." + defaultOutputExt(*format)
+    }
 
-    // Create CSV
-    csvFile, err := os.Create("git_projects_report.csv")
+    dest, closeDest, err := openOutput(outputPath)
     if err != nil {
-        fmt.Printf("❌ Failed to create CSV file: %v\n", err)
+        fmt.Printf("❌ %v\n", err)
+        os.Exit(1)
+    }
+    defer closeDest()
 
-        return
+    out, err := newOutputWriter(*format, dest)
+    if err != nil {
+        fmt.Printf("❌ %v\n", err)
+        os.Exit(1)
     }
-    defer csvFile.Close()
 
-    writer := csv.NewWriter(csvFile)
-    defer writer.Flush()
+    if *format == "ndjson" {
+        // NDJSON has no array wrapper to close over the whole result set,
+        // so each project can be written as soon as the scanner produces
+        // it instead of waiting for the full walk to finish.
+        for project := range results {
+            out.WriteProject(project)
+        }
+    } else {
+        var gitProjects []GitProject
+        for project := range results {
+            gitProjects = append(gitProjects, project)
+        }
 
-    writer.Write([]string{"Project name", "Path", "Remote repository", "Last commit date"})
+        sort.Slice(gitProjects, func(i, j int) bool {
+            if gitProjects[i].RemoteRepo != "" && gitProjects[j].RemoteRepo != "" {
+                if gitProjects[i].RemoteRepo == gitProjects[j].RemoteRepo {
+                    return gitProjects[i].LastCommitDate.After(gitProjects[j].LastCommitDate)
+                }
 
-    for _, project := range gitProjects {
-        writer.Write([]string{
-            project.ProjectName,
-            project.Path,
-            project.RemoteRepo,
-            project.LastCommitDate.Format("2006-01-02 15:04:05"),
+                return gitProjects[i].RemoteRepo < gitProjects[j].RemoteRepo
+            }
+
+            return gitProjects[i].ProjectName < gitProjects[j].ProjectName
         })
+
+        for _, project := range gitProjects {
+            out.WriteProject(project)
+        }
     }
 
-    fmt.Println("✅ Report saved to 'git_projects_report.csv'")
+    if err := out.Close(); err != nil {
+        fmt.Printf("❌ Failed to write report: %v\n", err)
+        os.Exit(1)
+    }
 
-    return
+    fmt.Printf("✅ Report saved to '%s'\n", outputPath)
 }
 
-func getGitInfo(projectPath string) (string, time.Time, error) {
-    // Встановлюємо таймаут 10 секунд для операцій git
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-    defer cancel()
+// gitOpTimeout is the per-operation timeout getGitInfo preserves from the
+// tool's original implementation.
+const gitOpTimeout = 10 * time.Second
+
+// topAuthorsWindow is how far back TopAuthors looks for --deep scans.
+const topAuthorsWindow = 90 * 24 * time.Hour
+
+// getGitInfo reads a project's git metadata through the given Repository
+// backend. The remote URL and last commit date are always fetched; branch,
+// ahead/behind, dirty state, commit count, and top authors are additionally
+// collected when opts.deep is set, since they cost an extra git operation
+// each.
+func getGitInfo(ctx context.Context, repo Repository, projectPath string, opts scanOptions) (GitProject, error) {
+    var project GitProject
+
+    remoteCtx, cancel := context.WithTimeout(ctx, gitOpTimeout)
+    remoteRepo, err := repo.RemoteURL(remoteCtx, projectPath)
+    cancel()
+    if err != nil {
+        // Логуємо, але продовжуємо
+        fmt.Printf("⚠️ [%s] Failed to get remote repo: %v\n", projectPath, err)
+        remoteRepo = ""
+    }
+    project.RemoteRepo = remoteRepo
 
-    var devNull *os.File
-    // Спробуємо відкрити /dev/null один раз
-    // Важливо: обробка помилки відкриття devNull є окремою від помилок git
-    if dn, err := os.Open(os.DevNull); err == nil {
-        devNull = dn
-        defer devNull.Close()
+    logCtx, cancel := context.WithTimeout(ctx, gitOpTimeout)
+    lastCommitDate, err := repo.LastCommitDate(logCtx, projectPath)
+    cancel()
+    if err != nil {
+        return project, err
     }
+    project.LastCommitDate = lastCommitDate
 
-    // --- 1. Отримання Remote Repo ---
-    cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
-    cmd.Dir = projectPath
-    // Якщо вдалося відкрити /dev/null, перенаправляємо stderr туди
-    if devNull != nil {
-        cmd.Stderr = devNull
+    if !opts.deep {
+        return project, nil
     }
 
-    remoteRepoBytes, err := cmd.Output()
-    remoteRepo := ""
+    branchCtx, cancel := context.WithTimeout(ctx, gitOpTimeout)
+    branch, err := repo.Branch(branchCtx, projectPath)
+    cancel()
+    if err != nil {
+        fmt.Printf("⚠️ [%s] Failed to get branch: %v\n", projectPath, err)
+    } else {
+        project.Branch = branch
+    }
 
+    aheadBehindCtx, cancel := context.WithTimeout(ctx, gitOpTimeout)
+    ahead, behind, err := repo.AheadBehind(aheadBehindCtx, projectPath)
+    cancel()
     if err != nil {
-        // Перевіряємо таймаут
-        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-            return "", time.Time{}, fmt.Errorf("Git remote operation timed out after 10s")
-        }
-        // Логуємо, але продовжуємо
-        fmt.Printf("⚠️ [%s] Failed to get remote repo: %v\n", projectPath, err)
+        fmt.Printf("⚠️ [%s] Failed to get ahead/behind counts: %v\n", projectPath, err)
     } else {
-        remoteRepo = strings.TrimSpace(string(remoteRepoBytes))
+        project.Ahead = ahead
+        project.Behind = behind
     }
 
-    // --- 2. Отримання дати останнього коміту ---
-    cmd = exec.CommandContext(ctx, "git", "log", "-1", "--format=%cd", "--date=iso")
-    cmd.Dir = projectPath
-    if devNull != nil {
-        cmd.Stderr = devNull
+    dirtyCtx, cancel := context.WithTimeout(ctx, gitOpTimeout)
+    dirty, err := repo.IsDirty(dirtyCtx, projectPath)
+    cancel()
+    if err != nil {
+        fmt.Printf("⚠️ [%s] Failed to get working tree status: %v\n", projectPath, err)
+    } else {
+        project.Dirty = dirty
     }
 
-    lastCommitBytes, err := cmd.Output()
+    commitCountCtx, cancel := context.WithTimeout(ctx, gitOpTimeout)
+    commitCount, err := repo.CommitCount(commitCountCtx, projectPath)
+    cancel()
     if err != nil {
-        // Перевіряємо, чи таймаут був причиною
-        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-            return remoteRepo, time.Time{}, fmt.Errorf("Git log operation timed out after 10s")
-        }
-        return remoteRepo, time.Time{}, fmt.Errorf("Failed to get last commit date: %w", err)
+        fmt.Printf("⚠️ [%s] Failed to get commit count: %v\n", projectPath, err)
+    } else {
+        project.CommitCount = commitCount
     }
 
-    lastCommitDateStr := strings.TrimSpace(string(lastCommitBytes))
-    // Виправлення: git log --date=iso виводить "2024-05-15 15:00:00 +0300",
-    // ваш формат правильний.
-    lastCommitDate, err := time.Parse("2006-01-02 15:04:05 -0700", lastCommitDateStr)
+    topAuthorsCtx, cancel := context.WithTimeout(ctx, gitOpTimeout)
+    topAuthors, err := repo.TopAuthors(topAuthorsCtx, projectPath, opts.topAuthors, time.Now().Add(-topAuthorsWindow))
+    cancel()
     if err != nil {
-        return remoteRepo, time.Time{}, fmt.Errorf("Failed to parse commit date '%s': %v", lastCommitDateStr, err)
+        fmt.Printf("⚠️ [%s] Failed to get top authors: %v\n", projectPath, err)
+    } else {
+        project.TopAuthors = topAuthors
     }
 
-    return remoteRepo, lastCommitDate, nil
+    return project, nil
 }
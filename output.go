@@ -0,0 +1,198 @@
+package main
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// formatTopAuthors renders top authors as "email(commits); email(commits)"
+// for the flat text formats (csv, md).
+func formatTopAuthors(authors []AuthorStat) string {
+    parts := make([]string, 0, len(authors))
+    for _, a := range authors {
+        parts = append(parts, a.Email+"("+strconv.Itoa(a.Commits)+")")
+    }
+
+    return strings.Join(parts, "; ")
+}
+
+// OutputWriter writes GitProjects in some serialization format to an
+// underlying writer. WriteProject may be called once per project as
+// results become available; Close finalizes the output (e.g. closing a
+// JSON array) and must be called exactly once, after the last
+// WriteProject.
+type OutputWriter interface {
+    WriteProject(p GitProject) error
+    Close() error
+}
+
+// newOutputWriter builds the OutputWriter selected by --format.
+func newOutputWriter(format string, w io.Writer) (OutputWriter, error) {
+    switch format {
+    case "", "csv":
+        return newCSVWriter(w), nil
+    case "json":
+        return newJSONWriter(w), nil
+    case "ndjson":
+        return newNDJSONWriter(w), nil
+    case "md":
+        return newMarkdownWriter(w), nil
+    default:
+        return nil, fmt.Errorf("unknown --format %q (want csv, json, ndjson, or md)", format)
+    }
+}
+
+// defaultOutputExt maps --format to the file extension used when --output
+// isn't given explicitly, so e.g. --format=json doesn't default into a
+// file named git_projects_report.csv.
+func defaultOutputExt(format string) string {
+    switch format {
+    case "json":
+        return "json"
+    case "ndjson":
+        return "ndjson"
+    case "md":
+        return "md"
+    default:
+        return "csv"
+    }
+}
+
+// openOutput resolves --output into a writer and a close func. "" or "-"
+// means stdout, which the caller must not close.
+func openOutput(path string) (io.Writer, func() error, error) {
+    if path == "" || path == "-" {
+        return os.Stdout, func() error { return nil }, nil
+    }
+
+    f, err := os.Create(path)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to create output file: %w", err)
+    }
+
+    return f, f.Close, nil
+}
+
+// csvOutputWriter preserves the tool's original CSV report format.
+type csvOutputWriter struct {
+    w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvOutputWriter {
+    cw := csv.NewWriter(w)
+    cw.Write([]string{
+        "Project name", "Path", "Remote repository", "Last commit date",
+        "Branch", "Ahead", "Behind", "Dirty", "Commit count", "Top authors",
+    })
+
+    return &csvOutputWriter{w: cw}
+}
+
+func (c *csvOutputWriter) WriteProject(p GitProject) error {
+    if err := c.w.Write([]string{
+        p.ProjectName,
+        p.Path,
+        p.RemoteRepo,
+        p.LastCommitDate.Format("2006-01-02 15:04:05"),
+        p.Branch,
+        strconv.Itoa(p.Ahead),
+        strconv.Itoa(p.Behind),
+        strconv.FormatBool(p.Dirty),
+        strconv.Itoa(p.CommitCount),
+        formatTopAuthors(p.TopAuthors),
+    }); err != nil {
+        return err
+    }
+
+    c.w.Flush()
+
+    return c.w.Error()
+}
+
+func (c *csvOutputWriter) Close() error {
+    c.w.Flush()
+    return c.w.Error()
+}
+
+// jsonOutputWriter buffers projects and writes a single JSON array on
+// Close, since a JSON array can't be streamed incrementally.
+type jsonOutputWriter struct {
+    w        io.Writer
+    projects []GitProject
+}
+
+func newJSONWriter(w io.Writer) *jsonOutputWriter {
+    return &jsonOutputWriter{w: w}
+}
+
+func (j *jsonOutputWriter) WriteProject(p GitProject) error {
+    j.projects = append(j.projects, p)
+    return nil
+}
+
+func (j *jsonOutputWriter) Close() error {
+    enc := json.NewEncoder(j.w)
+    enc.SetIndent("", "  ")
+
+    return enc.Encode(j.projects)
+}
+
+// ndjsonOutputWriter writes one GitProject JSON object per line as each
+// result becomes available, which pairs well with the concurrent scanner
+// and with piping into jq or a log pipeline.
+type ndjsonOutputWriter struct {
+    enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonOutputWriter {
+    return &ndjsonOutputWriter{enc: json.NewEncoder(w)}
+}
+
+func (n *ndjsonOutputWriter) WriteProject(p GitProject) error {
+    return n.enc.Encode(p)
+}
+
+func (n *ndjsonOutputWriter) Close() error {
+    return nil
+}
+
+// markdownOutputWriter renders a GitHub-flavored Markdown table.
+type markdownOutputWriter struct {
+    w           io.Writer
+    wroteHeader bool
+}
+
+func newMarkdownWriter(w io.Writer) *markdownOutputWriter {
+    return &markdownOutputWriter{w: w}
+}
+
+func (m *markdownOutputWriter) writeHeader() {
+    fmt.Fprintln(m.w, "| Project name | Path | Remote repository | Last commit date | Branch | Ahead | Behind | Dirty | Commit count | Top authors |")
+    fmt.Fprintln(m.w, "|---|---|---|---|---|---|---|---|---|---|")
+    m.wroteHeader = true
+}
+
+func (m *markdownOutputWriter) WriteProject(p GitProject) error {
+    if !m.wroteHeader {
+        m.writeHeader()
+    }
+
+    _, err := fmt.Fprintf(m.w, "| %s | %s | %s | %s | %s | %d | %d | %t | %d | %s |\n",
+        p.ProjectName, p.Path, p.RemoteRepo, p.LastCommitDate.Format("2006-01-02 15:04:05"),
+        p.Branch, p.Ahead, p.Behind, p.Dirty, p.CommitCount, formatTopAuthors(p.TopAuthors))
+
+    return err
+}
+
+func (m *markdownOutputWriter) Close() error {
+    if !m.wroteHeader {
+        m.writeHeader()
+    }
+
+    return nil
+}
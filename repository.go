@@ -0,0 +1,548 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/go-git/go-git/v5"
+    "github.com/go-git/go-git/v5/plumbing"
+    "github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// AuthorStat is a single author's commit count within a given window.
+type AuthorStat struct {
+    Email   string `json:"email"`
+    Commits int    `json:"commits"`
+}
+
+// Repository abstracts the git operations getGitInfo needs, so the scanner
+// can be backed either by shelling out to the git binary or by reading the
+// repository natively via go-git.
+type Repository interface {
+    // RemoteURL returns the "origin" remote URL, or "" if none is configured.
+    RemoteURL(ctx context.Context, projectPath string) (string, error)
+    // LastCommitDate returns the committer date of the newest commit
+    // reachable from HEAD.
+    LastCommitDate(ctx context.Context, projectPath string) (time.Time, error)
+    // CommitDatesByAuthor returns the author dates of every commit reachable
+    // from HEAD, authored by email, no older than since.
+    CommitDatesByAuthor(ctx context.Context, projectPath, email string, since time.Time) ([]time.Time, error)
+    // Branch returns the current branch's short name, or "" if HEAD is
+    // detached.
+    Branch(ctx context.Context, projectPath string) (string, error)
+    // AheadBehind returns how many commits HEAD is ahead of and behind its
+    // upstream, or (0, 0) if no upstream is configured.
+    AheadBehind(ctx context.Context, projectPath string) (ahead, behind int, err error)
+    // IsDirty reports whether the working tree has uncommitted changes.
+    IsDirty(ctx context.Context, projectPath string) (bool, error)
+    // CommitCount returns the total number of commits reachable from HEAD.
+    CommitCount(ctx context.Context, projectPath string) (int, error)
+    // TopAuthors returns up to n authors by commit count, most commits
+    // first, among commits reachable from HEAD no older than since.
+    TopAuthors(ctx context.Context, projectPath string, n int, since time.Time) ([]AuthorStat, error)
+}
+
+// newRepository builds the Repository implementation selected by --backend.
+func newRepository(backend string) (Repository, error) {
+    switch backend {
+    case "", "exec":
+        return execRepository{}, nil
+    case "native":
+        return nativeRepository{}, nil
+    default:
+        return nil, fmt.Errorf("unknown --backend %q (want exec or native)", backend)
+    }
+}
+
+// execRepository shells out to the git binary, same as the tool's original
+// implementation.
+type execRepository struct{}
+
+// gitCommand builds a git invocation in dir with stderr discarded, so
+// git's own diagnostic noise doesn't leak into the tool's output. The
+// returned cleanup func must be called once the command has finished.
+func gitCommand(ctx context.Context, dir string, args ...string) (*exec.Cmd, func()) {
+    cmd := exec.CommandContext(ctx, "git", args...)
+    cmd.Dir = dir
+
+    cleanup := func() {}
+    if devNull, err := os.Open(os.DevNull); err == nil {
+        cmd.Stderr = devNull
+        cleanup = func() { devNull.Close() }
+    }
+
+    return cmd, cleanup
+}
+
+func (execRepository) RemoteURL(ctx context.Context, projectPath string) (string, error) {
+    cmd, cleanup := gitCommand(ctx, projectPath, "remote", "get-url", "origin")
+    defer cleanup()
+
+    out, err := cmd.Output()
+    if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return "", fmt.Errorf("git remote operation timed out after 10s")
+        }
+        // No origin remote configured isn't fatal, just leave it blank.
+        return "", nil
+    }
+
+    return strings.TrimSpace(string(out)), nil
+}
+
+func (execRepository) LastCommitDate(ctx context.Context, projectPath string) (time.Time, error) {
+    cmd, cleanup := gitCommand(ctx, projectPath, "log", "-1", "--format=%cd", "--date=iso")
+    defer cleanup()
+
+    out, err := cmd.Output()
+    if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return time.Time{}, fmt.Errorf("git log operation timed out after 10s")
+        }
+        return time.Time{}, fmt.Errorf("failed to get last commit date: %w", err)
+    }
+
+    lastCommitDateStr := strings.TrimSpace(string(out))
+    lastCommitDate, err := time.Parse("2006-01-02 15:04:05 -0700", lastCommitDateStr)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("failed to parse commit date '%s': %v", lastCommitDateStr, err)
+    }
+
+    return lastCommitDate, nil
+}
+
+func (execRepository) CommitDatesByAuthor(ctx context.Context, projectPath, email string, since time.Time) ([]time.Time, error) {
+    cmd, cleanup := gitCommand(ctx, projectPath, "log",
+        "--since="+since.Format("2006-01-02"),
+        "--author="+email,
+        "--format=%cd", "--date=short")
+    defer cleanup()
+
+    out, err := cmd.Output()
+    if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return nil, fmt.Errorf("git log operation timed out after 10s")
+        }
+        return nil, fmt.Errorf("failed to get commit history: %w", err)
+    }
+
+    var dates []time.Time
+    for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+        if line == "" {
+            continue
+        }
+        d, err := time.Parse("2006-01-02", line)
+        if err != nil {
+            continue
+        }
+        dates = append(dates, d)
+    }
+
+    return dates, nil
+}
+
+func (execRepository) Branch(ctx context.Context, projectPath string) (string, error) {
+    cmd, cleanup := gitCommand(ctx, projectPath, "symbolic-ref", "--short", "HEAD")
+    defer cleanup()
+
+    out, err := cmd.Output()
+    if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return "", fmt.Errorf("git symbolic-ref operation timed out after 10s")
+        }
+        // Most likely a detached HEAD; not fatal.
+        return "", nil
+    }
+
+    return strings.TrimSpace(string(out)), nil
+}
+
+func (execRepository) AheadBehind(ctx context.Context, projectPath string) (int, int, error) {
+    cmd, cleanup := gitCommand(ctx, projectPath, "rev-list", "--left-right", "--count", "@{u}...HEAD")
+    defer cleanup()
+
+    out, err := cmd.Output()
+    if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return 0, 0, fmt.Errorf("git rev-list operation timed out after 10s")
+        }
+        // No upstream configured isn't fatal.
+        return 0, 0, nil
+    }
+
+    fields := strings.Fields(strings.TrimSpace(string(out)))
+    if len(fields) != 2 {
+        return 0, 0, fmt.Errorf("unexpected rev-list output %q", out)
+    }
+
+    behind, err := strconv.Atoi(fields[0])
+    if err != nil {
+        return 0, 0, fmt.Errorf("failed to parse behind count: %w", err)
+    }
+
+    ahead, err := strconv.Atoi(fields[1])
+    if err != nil {
+        return 0, 0, fmt.Errorf("failed to parse ahead count: %w", err)
+    }
+
+    return ahead, behind, nil
+}
+
+func (execRepository) IsDirty(ctx context.Context, projectPath string) (bool, error) {
+    cmd, cleanup := gitCommand(ctx, projectPath, "status", "--porcelain")
+    defer cleanup()
+
+    out, err := cmd.Output()
+    if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return false, fmt.Errorf("git status operation timed out after 10s")
+        }
+        return false, fmt.Errorf("failed to get working tree status: %w", err)
+    }
+
+    return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
+func (execRepository) CommitCount(ctx context.Context, projectPath string) (int, error) {
+    cmd, cleanup := gitCommand(ctx, projectPath, "rev-list", "--count", "HEAD")
+    defer cleanup()
+
+    out, err := cmd.Output()
+    if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return 0, fmt.Errorf("git rev-list operation timed out after 10s")
+        }
+        return 0, fmt.Errorf("failed to get commit count: %w", err)
+    }
+
+    count, err := strconv.Atoi(strings.TrimSpace(string(out)))
+    if err != nil {
+        return 0, fmt.Errorf("failed to parse commit count: %w", err)
+    }
+
+    return count, nil
+}
+
+func (execRepository) TopAuthors(ctx context.Context, projectPath string, n int, since time.Time) ([]AuthorStat, error) {
+    cmd, cleanup := gitCommand(ctx, projectPath, "log", "--since="+since.Format("2006-01-02"), "--format=%ae")
+    defer cleanup()
+
+    out, err := cmd.Output()
+    if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return nil, fmt.Errorf("git log operation timed out after 10s")
+        }
+        return nil, fmt.Errorf("failed to get commit authors: %w", err)
+    }
+
+    return topAuthorsFromEmails(strings.Split(strings.TrimSpace(string(out)), "\n"), n), nil
+}
+
+// topAuthorsFromEmails tallies commit counts per author email and returns
+// up to n of them, most commits first.
+func topAuthorsFromEmails(emails []string, n int) []AuthorStat {
+    if n <= 0 {
+        return nil
+    }
+
+    counts := map[string]int{}
+    var order []string
+
+    for _, email := range emails {
+        if email == "" {
+            continue
+        }
+        if counts[email] == 0 {
+            order = append(order, email)
+        }
+        counts[email]++
+    }
+
+    sort.Slice(order, func(i, j int) bool {
+        return counts[order[i]] > counts[order[j]]
+    })
+
+    if len(order) > n {
+        order = order[:n]
+    }
+
+    stats := make([]AuthorStat, 0, len(order))
+    for _, email := range order {
+        stats = append(stats, AuthorStat{Email: email, Commits: counts[email]})
+    }
+
+    return stats
+}
+
+// nativeRepository reads the repository directly via go-git, without
+// shelling out to a git binary or requiring one to be installed.
+type nativeRepository struct{}
+
+func (nativeRepository) RemoteURL(ctx context.Context, projectPath string) (string, error) {
+    repo, err := git.PlainOpen(projectPath)
+    if err != nil {
+        return "", fmt.Errorf("failed to open repository: %w", err)
+    }
+
+    remote, err := repo.Remote("origin")
+    if err != nil {
+        if errors.Is(err, git.ErrRemoteNotFound) {
+            return "", nil
+        }
+        return "", fmt.Errorf("failed to read origin remote: %w", err)
+    }
+
+    urls := remote.Config().URLs
+    if len(urls) == 0 {
+        return "", nil
+    }
+
+    return urls[0], nil
+}
+
+func (nativeRepository) LastCommitDate(ctx context.Context, projectPath string) (time.Time, error) {
+    repo, err := git.PlainOpen(projectPath)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("failed to open repository: %w", err)
+    }
+
+    head, err := repo.Head()
+    if err != nil {
+        return time.Time{}, fmt.Errorf("failed to resolve HEAD: %w", err)
+    }
+
+    commitIter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+    if err != nil {
+        return time.Time{}, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+    defer commitIter.Close()
+
+    var newest time.Time
+    err = commitIter.ForEach(func(c *object.Commit) error {
+        if c.Committer.When.After(newest) {
+            newest = c.Committer.When
+        }
+        return nil
+    })
+    if err != nil {
+        return time.Time{}, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+
+    return newest, nil
+}
+
+func (nativeRepository) CommitDatesByAuthor(ctx context.Context, projectPath, email string, since time.Time) ([]time.Time, error) {
+    repo, err := git.PlainOpen(projectPath)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open repository: %w", err)
+    }
+
+    head, err := repo.Head()
+    if err != nil {
+        return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+    }
+
+    commitIter, err := repo.Log(&git.LogOptions{From: head.Hash(), Since: &since})
+    if err != nil {
+        return nil, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+    defer commitIter.Close()
+
+    var dates []time.Time
+    err = commitIter.ForEach(func(c *object.Commit) error {
+        // Since filters on committer time (see go-git's commit_walker_limit.go),
+        // and the exec backend's --since/%cd also key off committer date, so
+        // record the same date here rather than the author date.
+        if c.Author.Email == email {
+            dates = append(dates, c.Committer.When)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+
+    return dates, nil
+}
+
+func (nativeRepository) Branch(ctx context.Context, projectPath string) (string, error) {
+    repo, err := git.PlainOpen(projectPath)
+    if err != nil {
+        return "", fmt.Errorf("failed to open repository: %w", err)
+    }
+
+    head, err := repo.Head()
+    if err != nil {
+        return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+    }
+
+    if !head.Name().IsBranch() {
+        return "", nil
+    }
+
+    return head.Name().Short(), nil
+}
+
+func (nativeRepository) AheadBehind(ctx context.Context, projectPath string) (int, int, error) {
+    repo, err := git.PlainOpen(projectPath)
+    if err != nil {
+        return 0, 0, fmt.Errorf("failed to open repository: %w", err)
+    }
+
+    head, err := repo.Head()
+    if err != nil {
+        return 0, 0, fmt.Errorf("failed to resolve HEAD: %w", err)
+    }
+
+    if !head.Name().IsBranch() {
+        return 0, 0, nil
+    }
+
+    branchCfg, err := repo.Branch(head.Name().Short())
+    if err != nil || branchCfg.Merge == "" {
+        // No upstream configured for this branch.
+        return 0, 0, nil
+    }
+
+    remoteName := branchCfg.Remote
+    if remoteName == "" {
+        remoteName = "origin"
+    }
+
+    upstreamRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branchCfg.Merge.Short()), true)
+    if err != nil {
+        // No local tracking ref for the upstream, e.g. never fetched.
+        return 0, 0, nil
+    }
+
+    headAncestors, err := ancestorHashes(repo, head.Hash())
+    if err != nil {
+        return 0, 0, err
+    }
+
+    upstreamAncestors, err := ancestorHashes(repo, upstreamRef.Hash())
+    if err != nil {
+        return 0, 0, err
+    }
+
+    ahead := 0
+    for h := range headAncestors {
+        if !upstreamAncestors[h] {
+            ahead++
+        }
+    }
+
+    behind := 0
+    for h := range upstreamAncestors {
+        if !headAncestors[h] {
+            behind++
+        }
+    }
+
+    return ahead, behind, nil
+}
+
+// ancestorHashes returns the set of every commit hash reachable from start.
+func ancestorHashes(repo *git.Repository, start plumbing.Hash) (map[plumbing.Hash]bool, error) {
+    set := map[plumbing.Hash]bool{}
+
+    iter, err := repo.Log(&git.LogOptions{From: start})
+    if err != nil {
+        return nil, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+    defer iter.Close()
+
+    err = iter.ForEach(func(c *object.Commit) error {
+        set[c.Hash] = true
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+
+    return set, nil
+}
+
+func (nativeRepository) IsDirty(ctx context.Context, projectPath string) (bool, error) {
+    repo, err := git.PlainOpen(projectPath)
+    if err != nil {
+        return false, fmt.Errorf("failed to open repository: %w", err)
+    }
+
+    wt, err := repo.Worktree()
+    if err != nil {
+        return false, fmt.Errorf("failed to open worktree: %w", err)
+    }
+
+    status, err := wt.Status()
+    if err != nil {
+        return false, fmt.Errorf("failed to get working tree status: %w", err)
+    }
+
+    return !status.IsClean(), nil
+}
+
+func (nativeRepository) CommitCount(ctx context.Context, projectPath string) (int, error) {
+    repo, err := git.PlainOpen(projectPath)
+    if err != nil {
+        return 0, fmt.Errorf("failed to open repository: %w", err)
+    }
+
+    head, err := repo.Head()
+    if err != nil {
+        return 0, fmt.Errorf("failed to resolve HEAD: %w", err)
+    }
+
+    commitIter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+    if err != nil {
+        return 0, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+    defer commitIter.Close()
+
+    count := 0
+    err = commitIter.ForEach(func(c *object.Commit) error {
+        count++
+        return nil
+    })
+    if err != nil {
+        return 0, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+
+    return count, nil
+}
+
+func (nativeRepository) TopAuthors(ctx context.Context, projectPath string, n int, since time.Time) ([]AuthorStat, error) {
+    repo, err := git.PlainOpen(projectPath)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open repository: %w", err)
+    }
+
+    head, err := repo.Head()
+    if err != nil {
+        return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+    }
+
+    commitIter, err := repo.Log(&git.LogOptions{From: head.Hash(), Since: &since})
+    if err != nil {
+        return nil, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+    defer commitIter.Close()
+
+    var emails []string
+    err = commitIter.ForEach(func(c *object.Commit) error {
+        emails = append(emails, c.Author.Email)
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to walk commit graph: %w", err)
+    }
+
+    return topAuthorsFromEmails(emails, n), nil
+}
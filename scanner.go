@@ -0,0 +1,168 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "sync"
+    "time"
+)
+
+// scanOptions configures the discovery/worker pipeline.
+type scanOptions struct {
+    workers    int
+    verbose    bool
+    deep       bool
+    exclude    []string
+    topAuthors int
+}
+
+// isExcluded reports whether name matches any of the --exclude globs.
+func isExcluded(exclude []string, name string) bool {
+    for _, pattern := range exclude {
+        if matched, _ := filepath.Match(pattern, name); matched {
+            return true
+        }
+    }
+    return false
+}
+
+// discoverGitDirs walks rootDir looking for .git directories and sends the
+// path of each project (the parent of the .git dir) on the returned
+// channel. The channel is closed once the walk finishes or ctx is
+// cancelled.
+func discoverGitDirs(ctx context.Context, rootDir string, opts scanOptions) <-chan string {
+    out := make(chan string)
+
+    go func() {
+        defer close(out)
+
+        err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+            if ctx.Err() != nil {
+                return ctx.Err()
+            }
+
+            if err != nil {
+                fmt.Printf("🚫 Skipped due to error in %s: %v\n", path, err)
+                return nil
+            }
+
+            if !info.IsDir() {
+                return nil
+            }
+
+            if isExcluded(opts.exclude, info.Name()) {
+                if opts.verbose {
+                    printAndFlush(fmt.Sprintf("Excluding: %s\n", path))
+                }
+                return filepath.SkipDir
+            }
+
+            if opts.verbose {
+                printAndFlush(fmt.Sprintf("Entering: %s\n", path))
+            }
+
+            if strings.HasSuffix(path, "/.git") {
+                projectPath := filepath.Dir(path)
+                select {
+                case out <- projectPath:
+                case <-ctx.Done():
+                    return ctx.Err()
+                }
+                // No need to walk the internals of .git itself.
+                return filepath.SkipDir
+            }
+
+            return nil
+        })
+
+        if err != nil && err != context.Canceled {
+            fmt.Printf("🚫 Error while scanning '%s': %v\n", rootDir, err)
+        }
+    }()
+
+    return out
+}
+
+// runScan discovers git repositories under each of the given root
+// directories and inspects them with a bounded pool of workers, so a scan
+// of many repositories doesn't run getGitInfo serially. Progress for each
+// repository is reported as a structured "SCAN ..." line so output stays
+// coherent under concurrency. Results are streamed out on the returned
+// channel as each repository finishes, rather than buffered until the
+// whole walk completes.
+func runScan(ctx context.Context, directories []string, repo Repository, opts scanOptions) <-chan GitProject {
+    workers := opts.workers
+    if workers <= 0 {
+        workers = runtime.NumCPU()
+    }
+
+    paths := make(chan string)
+
+    go func() {
+        defer close(paths)
+
+        for _, rootDir := range directories {
+            rootDir = strings.TrimSpace(rootDir)
+            fmt.Printf("🔍 Scanning: %s\n", rootDir)
+
+            for path := range discoverGitDirs(ctx, rootDir, opts) {
+                select {
+                case paths <- path:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }
+    }()
+
+    results := make(chan GitProject)
+    var wg sync.WaitGroup
+    wg.Add(workers)
+
+    for i := 0; i < workers; i++ {
+        go func() {
+            defer wg.Done()
+
+            for projectPath := range paths {
+                start := time.Now()
+                project, err := getGitInfo(ctx, repo, projectPath, opts)
+                elapsed := time.Since(start).Milliseconds()
+
+                if err != nil {
+                    reportProgress(projectPath, "error", elapsed)
+                    fmt.Printf("!❌ [%s] Skipping project due to error: %v\n", projectPath, err)
+                    continue
+                }
+
+                reportProgress(projectPath, "done", elapsed)
+
+                project.Path = projectPath
+                project.ProjectName = filepath.Base(projectPath)
+
+                select {
+                case results <- project:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }()
+    }
+
+    go func() {
+        wg.Wait()
+        close(results)
+    }()
+
+    return results
+}
+
+// reportProgress emits a single structured progress line, e.g.
+// "SCAN path=/repos/foo state=done elapsed=142ms". Using one Print call
+// per line keeps output coherent when many workers report at once.
+func reportProgress(path, state string, elapsedMs int64) {
+    printAndFlush(fmt.Sprintf("SCAN path=%s state=%s elapsed=%dms\n", path, state, elapsedMs))
+}